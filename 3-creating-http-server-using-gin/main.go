@@ -2,100 +2,143 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
-	"slices"
-
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/config"
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/models"
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/pagination"
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/repositories"
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/utils/validate"
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// define the Todo struct
-type Todo struct {
-	ID        string `json:"id"`
-	Item      string `json:"item"`
-	Completed bool   `json:"completed"`
-}
+// todos is the repository used by all todo handlers; it is wired up in main
+var todos *repositories.TodoRepository
 
-// define a few todos
-var TODOS = []Todo{
-	{ID: "1", Item: "Buy groceries", Completed: false},
-	{ID: "2", Item: "Read a book", Completed: false},
-	{ID: "3", Item: "Write code", Completed: false},
-	{ID: "4", Item: "Go for a walk", Completed: false},
+// parseTodoID converts a URL path parameter into a Mongo ObjectID
+func parseTodoID(context *gin.Context) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(context.Param("id"))
+	if err != nil {
+		return primitive.NilObjectID, errors.New("invalid todo ID")
+	}
+	return id, nil
 }
 
-// containsIgnoreCase is a helper function that checks if a string contains another string
-func containsIgnoreCase(str, substr string) bool {
-	return strings.Contains(strings.ToLower(str), strings.ToLower(substr))
-}
+// todoSortSpec builds a Mongo sort spec for the ?sort= query parameter (e.g.
+// "item", "-completed"); a leading "-" requests descending order
+func todoSortSpec(sortParam string) (bson.D, error) {
+	if sortParam == "" {
+		return nil, nil
+	}
 
-// getTodoById is a helper function that retrieves a todo by its ID
-// it takes an ID as a string and returns the corresponding Todo or an error if not found
-func getTodoById(id string) (*Todo, error) {
-	for i := range TODOS {
-		if TODOS[i].ID == id {
-			return &TODOS[i], nil
-		}
+	order := 1
+	field := sortParam
+	if strings.HasPrefix(sortParam, "-") {
+		order = -1
+		field = strings.TrimPrefix(sortParam, "-")
+	}
+
+	switch field {
+	case "item", "completed":
+		return bson.D{{Key: field, Value: order}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", field)
 	}
-	return nil, errors.New("Todo not found")
 }
 
 // getTodos handles the GET request to retrieve all todos
-// it filters todos based on query parameters: ?query= and ?status=
-// it returns the filtered todos in the response body with a 200 OK status
+// it filters todos based on query parameters: ?query= and ?status=, and
+// paginates the result with ?limit=, ?cursor=, and ?sort=
 func getTodos(context *gin.Context) {
 	query := context.Query("query")
 	status := context.Query("status")
 
-	var filtered []Todo
+	filter := bson.M{}
 
-	for _, todo := range TODOS {
-		// Match query if provided
-		if query != "" && !containsIgnoreCase(todo.Item, query) {
-			continue
-		}
+	if query != "" {
+		filter["item"] = bson.M{"$regex": query, "$options": "i"}
+	}
 
-		// Match status if provided
-		if status == "true" && !todo.Completed {
-			continue
-		}
-		if status == "false" && todo.Completed {
-			continue
-		}
+	if status == "true" || status == "false" {
+		filter["completed"] = status == "true"
+	}
 
-		filtered = append(filtered, todo)
+	sort, err := todoSortSpec(context.Query("sort"))
+	if err != nil {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	limit, err := strconv.Atoi(context.DefaultQuery("limit", strconv.Itoa(pagination.DefaultLimit)))
+	if err != nil || limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+
+	opts := pagination.Opts{Limit: limit, Cursor: context.Query("cursor"), Sort: sort}
+
+	total, err := todos.Count(filter)
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch todos"})
+		return
+	}
+
+	page, err := todos.Find(filter, pagination.FindOptions(opts))
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch todos"})
+		return
 	}
 
-	context.IndentedJSON(http.StatusOK, filtered)
+	nextCursor, prevCursor := pagination.Cursors(opts, total, len(page))
+
+	context.IndentedJSON(http.StatusOK, gin.H{
+		"todos":       page,
+		"total":       total,
+		"next_cursor": nextCursor,
+		"prev_cursor": prevCursor,
+	})
 }
 
 // addTodo handles the POST request to add a new todo
 // it takes context as an argument, context holds the info about the HTTP request
-// it binds the JSON from the request body to a new Todo struct and appends it to the TODOS slice
-// it returns a 201 Created status with the new todo in the response body
-// if there is an error binding the JSON, it simply returns without doing anything
+// it binds and validates the JSON from the request body against Todo's binding
+// tags, returning a 422 with field-level errors if validation fails, and
+// persists the new todo with a 201 Created status otherwise
 func addTodo(context *gin.Context) {
-	var newTodo Todo
+	var newTodo models.Todo
 
-	if err := context.BindJSON(&newTodo); err != nil {
-		context.IndentedJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := context.ShouldBindJSON(&newTodo); err != nil {
+		context.IndentedJSON(http.StatusUnprocessableEntity, gin.H{"errors": validate.FormatErrors(err)})
 		return
 	}
 
-	TODOS = append(TODOS, newTodo)
+	if err := todos.Create(&newTodo); err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{"error": "Failed to create todo"})
+		return
+	}
 
 	context.IndentedJSON(http.StatusCreated, newTodo)
 }
 
 // getTodo handles the GET request to retrieve a specific todo by its ID
-// it retrieves the ID from the URL parameters, calls getTodoById to find the todo,
+// it retrieves the ID from the URL parameters, looks up the todo,
 // and returns it in the response body with a 200 OK status
 // if the todo is not found, it returns a 404 Not Found status with an error
 func getTodo(context *gin.Context) {
-	id := context.Param("id")
-	todo, err := getTodoById(id)
+	id, err := parseTodoID(context)
+	if err != nil {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Invalid todo ID"})
+		return
+	}
 
+	todo, err := todos.FindByID(id)
 	if err != nil {
 		context.IndentedJSON(http.StatusNotFound, gin.H{"message": "Todo not found"})
 		return
@@ -105,41 +148,58 @@ func getTodo(context *gin.Context) {
 }
 
 // updateTodoStatus handles the PATCH request to toggle the completed status of a todo
-// it retrieves the ID from the URL parameters, calls getTodoById to find the todo,
+// it retrieves the ID from the URL parameters, looks up the todo,
 // toggles its Completed status, and returns the updated todo in the response body with a
 // 200 OK status
 // if the todo is not found, it returns a 404 Not Found status with an error message
 func updateTodoStatus(context *gin.Context) {
-	id := context.Param("id")
-	todo, err := getTodoById(id)
+	id, err := parseTodoID(context)
+	if err != nil {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Invalid todo ID"})
+		return
+	}
 
+	todo, err := todos.FindByID(id)
 	if err != nil {
 		context.IndentedJSON(http.StatusNotFound, gin.H{"message": "Todo not found"})
 		return
 	}
 
-	todo.Completed = !todo.Completed // toggle the completed status
+	updated, err := todos.Update(id, bson.M{"completed": !todo.Completed})
+	if err != nil {
+		context.IndentedJSON(http.StatusInternalServerError, gin.H{"message": "Failed to update todo"})
+		return
+	}
 
-	context.IndentedJSON(http.StatusOK, todo)
+	context.IndentedJSON(http.StatusOK, updated)
 }
 
 // deleteTodo handles the DELETE request to remove a todo by its ID
-// it retrieves the ID from the URL parameters, searches for the todo in the TODOS slice,
-// and removes it if found using the slices.Delete function from the slices package
+// it retrieves the ID from the URL parameters and removes the matching todo
 func deleteTodo(context *gin.Context) {
-	id := context.Param("id")
-	for i, todo := range TODOS {
-		if todo.ID == id {
-			TODOS = slices.Delete(TODOS, i, i+1)
-			context.IndentedJSON(http.StatusOK, gin.H{"message": "Todo deleted"})
-			return
-		}
-	}
-	context.IndentedJSON(http.StatusNotFound, gin.H{"message": "Todo not found"})
+	id, err := parseTodoID(context)
+	if err != nil {
+		context.IndentedJSON(http.StatusBadRequest, gin.H{"message": "Invalid todo ID"})
+		return
+	}
+
+	if err := todos.Delete(id); err != nil {
+		context.IndentedJSON(http.StatusNotFound, gin.H{"message": "Todo not found"})
+		return
+	}
+
+	context.IndentedJSON(http.StatusOK, gin.H{"message": "Todo deleted"})
 }
 
 // main function sets up the Gin router and defines the routes for the todo API
 func main() {
+	cfg := config.Load()
+	db, err := config.Connect(cfg)
+	if err != nil {
+		panic(err)
+	}
+	todos = repositories.NewTodoRepository(db)
+
 	router := gin.Default()
 	router.GET("/todos", getTodos)
 	router.POST("/todos", addTodo)
@@ -0,0 +1,10 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Todo represents a single item on the todo list
+type Todo struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Item      string             `json:"item" bson:"item" binding:"required,min=1,max=200"`
+	Completed bool               `json:"completed" bson:"completed"`
+}
@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TechAtikiN/the-go-brewery/3-creating-http-server-using-gin/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// TodoRepository persists Todo documents in the "todos" collection
+type TodoRepository struct {
+	collection *mongo.Collection
+}
+
+// NewTodoRepository wraps the given database's "todos" collection
+func NewTodoRepository(db *mongo.Database) *TodoRepository {
+	return &TodoRepository{collection: db.Collection("todos")}
+}
+
+func (r *TodoRepository) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+// Create inserts a new todo and populates its generated ID
+func (r *TodoRepository) Create(todo *models.Todo) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	todo.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, todo)
+	return err
+}
+
+// FindByID looks up a single todo by its ObjectID
+func (r *TodoRepository) FindByID(id primitive.ObjectID) (*models.Todo, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	var todo models.Todo
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&todo); err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// Find returns the todos matching the given Mongo filter, optionally
+// narrowed by Mongo find options such as Skip/Limit/Sort
+func (r *TodoRepository) Find(filter bson.M, opts ...*options.FindOptions) ([]models.Todo, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	todos := []models.Todo{}
+	if err := cursor.All(ctx, &todos); err != nil {
+		return nil, err
+	}
+
+	return todos, nil
+}
+
+// Count returns the number of todos matching the given Mongo filter
+func (r *TodoRepository) Count(filter bson.M) (int64, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// Update applies the given field updates to a todo and returns the updated document
+func (r *TodoRepository) Update(id primitive.ObjectID, update bson.M) (*models.Todo, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	after := options.After
+	var todo models.Todo
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": update},
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after},
+	).Decode(&todo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &todo, nil
+}
+
+// Delete removes a todo by its ObjectID
+func (r *TodoRepository) Delete(id primitive.ObjectID) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// Package validate turns the validator.ValidationErrors gin's binding layer
+// returns into an API-friendly field error list.
+package validate
+
+import (
+	"fmt"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single failed validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+// FormatErrors converts a binding error into field-level validation errors.
+// Non-validation errors (e.g. malformed JSON) come back as a single entry.
+func FormatErrors(err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	out := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		out[i] = FieldError{
+			Field:   fe.Field(),
+			Tag:     fe.Tag(),
+			Message: fmt.Sprintf("%s failed on the '%s' validation", fe.Field(), fe.Tag()),
+		}
+	}
+
+	return out
+}
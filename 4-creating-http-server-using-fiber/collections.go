@@ -0,0 +1,234 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/models"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/pagination"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/repositories"
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CollectionHandler groups the /collections routes and the repositories
+// they depend on to validate and join book references
+type CollectionHandler struct {
+	collections *repositories.CollectionRepository
+	books       *repositories.BookRepository
+}
+
+// NewCollectionHandler builds a CollectionHandler backed by the given repositories
+func NewCollectionHandler(collections *repositories.CollectionRepository, books *repositories.BookRepository) *CollectionHandler {
+	return &CollectionHandler{collections: collections, books: books}
+}
+
+// parseCollectionID converts a URL path parameter into a Mongo ObjectID
+func parseCollectionID(c *fiber.Ctx, param string) (primitive.ObjectID, error) {
+	return primitive.ObjectIDFromHex(c.Params(param))
+}
+
+// create handles POST /collections - creates a new, empty collection
+func (h *CollectionHandler) create(c *fiber.Ctx) error {
+	var col models.Collection
+
+	if err := c.BodyParser(&col); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Cannot parse JSON",
+			"details": err.Error(),
+		})
+	}
+
+	if col.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	col.BookIDs = []primitive.ObjectID{}
+
+	if err := h.collections.Create(&col); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create collection",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message":    "Collection created successfully",
+		"collection": col,
+	})
+}
+
+// list handles GET /collections?search=&page=&limit= - lists collections,
+// optionally filtered by name and paginated
+func (h *CollectionHandler) list(c *fiber.Ctx) error {
+	filter := bson.M{}
+	if search := c.Query("search"); search != "" {
+		filter["name"] = bson.M{"$regex": search, "$options": "i"}
+	}
+
+	page, err := strconv.ParseInt(c.Query("page", "1"), 10, 64)
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.ParseInt(c.Query("limit", "20"), 10, 64)
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+
+	cols, err := h.collections.Find(filter, page, limit)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch collections",
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"collections": cols,
+		"page":        page,
+		"limit":       limit,
+	})
+}
+
+// get handles GET /collections/:id - retrieves a single collection by ID
+func (h *CollectionHandler) get(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	col, err := h.collections.FindByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(col)
+}
+
+// update handles PUT /collections/:id - updates a collection's name/description
+func (h *CollectionHandler) update(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	var updates models.Collection
+	if err := c.BodyParser(&updates); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Cannot parse JSON",
+			"details": err.Error(),
+		})
+	}
+
+	if updates.Name == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Name is required",
+		})
+	}
+
+	col, err := h.collections.Update(id, bson.M{
+		"name":        updates.Name,
+		"description": updates.Description,
+	})
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Collection updated successfully",
+		"collection": col,
+	})
+}
+
+// delete handles DELETE /collections/:id - removes a collection
+func (h *CollectionHandler) delete(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	if err := h.collections.Delete(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{"message": "Collection deleted successfully"})
+}
+
+// addBook handles POST /collections/:id/books/:bookId - adds a book to a collection,
+// validating that the referenced book exists first
+func (h *CollectionHandler) addBook(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	bookID, err := parseCollectionID(c, "bookId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid book ID"})
+	}
+
+	if _, err := h.books.FindByID(bookID); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Book not found"})
+	}
+
+	col, err := h.collections.AddBook(id, bookID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Book added to collection",
+		"collection": col,
+	})
+}
+
+// removeBook handles DELETE /collections/:id/books/:bookId - removes a book from a collection
+func (h *CollectionHandler) removeBook(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	bookID, err := parseCollectionID(c, "bookId")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid book ID"})
+	}
+
+	col, err := h.collections.RemoveBook(id, bookID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":    "Book removed from collection",
+		"collection": col,
+	})
+}
+
+// booksInCollection handles GET /collections/:id/books - returns the joined Book
+// records referenced by a collection
+func (h *CollectionHandler) booksInCollection(c *fiber.Ctx) error {
+	id, err := parseCollectionID(c, "id")
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "Invalid collection ID"})
+	}
+
+	col, err := h.collections.FindByID(id)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{"error": "Collection not found"})
+	}
+
+	found, err := h.books.Find(bson.M{"_id": bson.M{"$in": col.BookIDs}})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{"error": "Failed to fetch books"})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"books": found,
+		"total": len(found),
+	})
+}
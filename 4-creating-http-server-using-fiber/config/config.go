@@ -0,0 +1,49 @@
+package config
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Config holds the environment-derived settings needed to reach MongoDB Atlas
+type Config struct {
+	MongoURI string
+	DBName   string
+}
+
+// Load reads MONGO_URI and DB_NAME from the process environment after loading
+// a .env file if present; variables already set in the environment take
+// precedence over values in the .env file
+func Load() Config {
+	if err := godotenv.Load(); err != nil {
+		log.Println("No .env file found, reading config from the environment")
+	}
+
+	return Config{
+		MongoURI: os.Getenv("MONGO_URI"),
+		DBName:   os.Getenv("DB_NAME"),
+	}
+}
+
+// Connect dials MongoDB Atlas using the given config and returns the target database
+func Connect(cfg Config) (*mongo.Database, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(cfg.MongoURI))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := client.Ping(ctx, nil); err != nil {
+		return nil, err
+	}
+
+	return client.Database(cfg.DBName), nil
+}
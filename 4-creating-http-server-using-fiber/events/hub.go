@@ -0,0 +1,87 @@
+// Package events is an in-process pub/sub hub that broadcasts library
+// mutations to SSE and WebSocket subscribers.
+package events
+
+import "sync"
+
+// subscriberBuffer bounds how many undelivered events a slow subscriber can
+// queue before new events are dropped for it
+const subscriberBuffer = 16
+
+// Event describes a single create/update/delete/toggle mutation
+type Event struct {
+	Object string `json:"object"`
+	Action string `json:"action"`
+	Data   any    `json:"data"`
+	Source string `json:"-"`
+}
+
+// Subscriber receives events over a buffered channel; Dropped counts events
+// discarded because the subscriber fell behind
+type Subscriber struct {
+	id      uint64
+	source  string
+	Events  chan Event
+	Dropped int
+}
+
+// Hub fans Published events out to every Subscriber except the one whose
+// source matches the event's Source, so the originating client does not see
+// an echo of its own mutation
+type Hub struct {
+	mu          sync.Mutex
+	nextID      uint64
+	subscribers map[uint64]*Subscriber
+}
+
+// NewHub creates an empty Hub
+func NewHub() *Hub {
+	return &Hub{subscribers: map[uint64]*Subscriber{}}
+}
+
+// Subscribe registers a new subscriber and returns it; source is the
+// requester's X-Request-Source header, used only to skip echoes in Publish,
+// never as the subscriber's identity. Callers must call Unsubscribe with the
+// returned Subscriber when the connection closes.
+func (h *Hub) Subscribe(source string) *Subscriber {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.nextID++
+	sub := &Subscriber{id: h.nextID, source: source, Events: make(chan Event, subscriberBuffer)}
+	h.subscribers[sub.id] = sub
+
+	return sub
+}
+
+// Unsubscribe removes a subscriber and closes its channel
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.subscribers[sub.id]; ok {
+		close(sub.Events)
+		delete(h.subscribers, sub.id)
+	}
+}
+
+// Publish broadcasts event to every subscriber except one whose source
+// matches event.Source. A subscriber whose buffer is full has the event
+// dropped and its Dropped counter incremented rather than blocking the
+// publisher.
+func (h *Hub) Publish(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, sub := range h.subscribers {
+		if sub.source != "" && sub.source == event.Source {
+			continue
+		}
+
+		select {
+		case sub.Events <- event:
+		default:
+			sub.Dropped++
+		}
+	}
+}
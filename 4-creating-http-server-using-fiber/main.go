@@ -2,97 +2,160 @@ package main
 
 import (
 	"errors"
+	"fmt"
 	"log"
-	"slices"
 	"strconv"
 	"strings"
 
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/config"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/models"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/pagination"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/repositories"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/utils/hal"
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/utils/validate"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// Book represents a book in our library
-type Book struct {
-	ID            int    `json:"id"`
-	Title         string `json:"title"`
-	Author        string `json:"author"`
-	Genre         string `json:"genre"`
-	Pages         int    `json:"pages"`
-	Available     bool   `json:"available"`
-	PublishedYear int    `json:"published_year"`
-}
+// books is the repository used by all book handlers; it is wired up in main
+var books *repositories.BookRepository
 
-// Our in-memory book storage
-var library = []Book{
-	{ID: 1, Title: "The Go Programming Language", Author: "Alan Donovan", Genre: "Technology", Pages: 380, Available: true, PublishedYear: 2015},
-	{ID: 2, Title: "Clean Code", Author: "Robert Martin", Genre: "Technology", Pages: 464, Available: false, PublishedYear: 2008},
-	{ID: 3, Title: "The Pragmatic Programmer", Author: "Dave Thomas", Genre: "Technology", Pages: 352, Available: true, PublishedYear: 1999},
-	{ID: 4, Title: "Design Patterns", Author: "Gang of Four", Genre: "Technology", Pages: 395, Available: true, PublishedYear: 1994},
+// parseBookID converts a URL path parameter into a Mongo ObjectID
+func parseBookID(c *fiber.Ctx) (primitive.ObjectID, error) {
+	id, err := primitive.ObjectIDFromHex(c.Params("id"))
+	if err != nil {
+		return primitive.NilObjectID, errors.New("invalid book ID")
+	}
+	return id, nil
 }
 
-var nextID = 5 // Simple counter for generating new IDs
-
-// containsIgnoreCase checks if a string contains another string (case-insensitive)
-func containsIgnoreCase(str, substr string) bool {
-	return strings.Contains(strings.ToLower(str), strings.ToLower(substr))
+// wantsLegacyJSON reports whether the client wants today's flat JSON shape
+// rather than the HAL envelope. Callers keep getting the legacy shape unless
+// they explicitly opt into application/hal+json via Accept, so clients that
+// send no Accept header (or "*/*", the default for curl and most HTTP
+// libraries) aren't silently switched over.
+func wantsLegacyJSON(c *fiber.Ctx) bool {
+	return !strings.Contains(c.Get("Accept"), "hal+json")
 }
 
-// bookMatchesSearch checks if a book matches the search term in title or author
-func bookMatchesSearch(book Book, searchTerm string) bool {
-	return containsIgnoreCase(book.Title, searchTerm) || containsIgnoreCase(book.Author, searchTerm)
+// bookResource wraps a book with its HAL self, collection, and toggle-availability links
+func bookResource(book models.Book) hal.Resource {
+	self := "/books/" + book.ID.Hex()
+	return hal.NewResource(self, book).
+		AddLink("collection", "/books").
+		AddLink("toggle-availability", self)
 }
 
-// findBookByID searches for a book by its ID and returns the book and its index
-func findBookByID(id int) (*Book, int, error) {
-	for i, book := range library {
-		if book.ID == id {
-			return &book, i, nil
-		}
+// bookSortSpec builds a Mongo sort spec for the ?sort= query parameter (e.g.
+// "title", "-published_year"); a leading "-" requests descending order
+func bookSortSpec(sortParam string) (bson.D, error) {
+	if sortParam == "" {
+		return nil, nil
+	}
+
+	order := 1
+	field := sortParam
+	if strings.HasPrefix(sortParam, "-") {
+		order = -1
+		field = strings.TrimPrefix(sortParam, "-")
+	}
+
+	switch field {
+	case "title", "author", "genre", "pages", "published_year":
+		return bson.D{{Key: field, Value: order}}, nil
+	default:
+		return nil, fmt.Errorf("unknown sort field %q", field)
 	}
-	return nil, -1, errors.New("book not found")
 }
 
 // getAllBooks handles GET /books - retrieves all books with optional filtering
-// supports query parameters: ?search=, ?genre=, ?available=
+// and cursor-based pagination
+// supports query parameters: ?search=, ?genre=, ?available=, ?limit=, ?cursor=, ?sort=
 func getAllBooks(c *fiber.Ctx) error {
-	search := c.Query("search")
-	genre := c.Query("genre")
-	availableStr := c.Query("available")
+	filter := bson.M{}
 
-	var filtered []Book
-
-	for _, book := range library {
-		// Filter by search term if provided
-		if search != "" && !bookMatchesSearch(book, search) {
-			continue
+	if search := c.Query("search"); search != "" {
+		filter["$or"] = []bson.M{
+			{"title": bson.M{"$regex": search, "$options": "i"}},
+			{"author": bson.M{"$regex": search, "$options": "i"}},
 		}
+	}
 
-		// Filter by genre if provided
-		if genre != "" && !strings.EqualFold(book.Genre, genre) {
-			continue
-		}
+	if genre := c.Query("genre"); genre != "" {
+		filter["genre"] = genre
+	}
 
-		// Filter by availability if provided
-		if availableStr != "" {
-			available, err := strconv.ParseBool(availableStr)
-			if err == nil && book.Available != available {
-				continue
-			}
+	if availableStr := c.Query("available"); availableStr != "" {
+		if available, err := strconv.ParseBool(availableStr); err == nil {
+			filter["available"] = available
 		}
+	}
 
-		filtered = append(filtered, book)
+	sort, err := bookSortSpec(c.Query("sort"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"books": filtered,
-		"total": len(filtered),
-	})
+	limit, err := strconv.Atoi(c.Query("limit", strconv.Itoa(pagination.DefaultLimit)))
+	if err != nil || limit <= 0 {
+		limit = pagination.DefaultLimit
+	}
+	if limit > pagination.MaxLimit {
+		limit = pagination.MaxLimit
+	}
+
+	opts := pagination.Opts{Limit: limit, Cursor: c.Query("cursor"), Sort: sort}
+
+	total, err := books.Count(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch books",
+		})
+	}
+
+	page, err := books.Find(filter, pagination.FindOptions(opts))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch books",
+		})
+	}
+
+	nextCursor, prevCursor := pagination.Cursors(opts, total, len(page))
+
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"books":       page,
+			"total":       total,
+			"next_cursor": nextCursor,
+			"prev_cursor": prevCursor,
+		})
+	}
+
+	items := make([]hal.Resource, len(page))
+	for i, book := range page {
+		items[i] = bookResource(book)
+	}
+
+	list := hal.NewResource(fmt.Sprintf("/books?limit=%d", limit), fiber.Map{"total": total}).
+		Embed("books", items...)
+
+	if nextCursor != "" {
+		list = list.AddLink("next", fmt.Sprintf("/books?limit=%d&cursor=%s", limit, nextCursor))
+	}
+	if prevCursor != "" {
+		list = list.AddLink("prev", fmt.Sprintf("/books?limit=%d&cursor=%s", limit, prevCursor))
+	}
+
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusOK).JSON(list)
 }
 
 // createBook handles POST /books - adds a new book to the library
 func createBook(c *fiber.Ctx) error {
-	var newBook Book
+	var newBook models.Book
 
 	if err := c.BodyParser(&newBook); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -101,62 +164,114 @@ func createBook(c *fiber.Ctx) error {
 		})
 	}
 
-	// Basic validation
-	if newBook.Title == "" || newBook.Author == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Title and Author are required",
+	if errs := validate.Struct(newBook); errs != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"errors": errs})
+	}
+
+	if err := books.Create(&newBook); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create book",
 		})
 	}
+	publish(c, "create", newBook)
 
-	// Assign ID and add to library
-	newBook.ID = nextID
-	nextID++
-	library = append(library, newBook)
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+			"message": "Book added successfully",
+			"book":    newBook,
+		})
+	}
 
-	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-		"message": "Book added successfully",
-		"book":    newBook,
-	})
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusCreated).JSON(bookResource(newBook))
 }
 
 // getBook handles GET /books/:id - retrieves a specific book by ID
 func getBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := parseBookID(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid book ID",
 		})
 	}
 
-	book, _, err := findBookByID(id)
+	book, err := books.FindByID(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Book not found",
 		})
 	}
 
-	return c.Status(fiber.StatusOK).JSON(book)
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusOK).JSON(book)
+	}
+
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusOK).JSON(bookResource(*book))
 }
 
-// updateBook handles PUT /books/:id - updates book information
+// updateBook handles PUT /books/:id - replaces a book's information. The
+// whole body is validated and every field is written, so omitted fields are
+// reset to their zero value; use PATCH /books/:id/fields to update a subset.
 func updateBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := parseBookID(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid book ID",
 		})
 	}
 
-	_, index, err := findBookByID(id)
+	var updates models.Book
+	if err := c.BodyParser(&updates); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Cannot parse JSON",
+			"details": err.Error(),
+		})
+	}
+
+	if errs := validate.Struct(updates); errs != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"errors": errs})
+	}
+
+	set := bson.M{
+		"title":          updates.Title,
+		"author":         updates.Author,
+		"genre":          updates.Genre,
+		"pages":          updates.Pages,
+		"available":      updates.Available,
+		"published_year": updates.PublishedYear,
+	}
+
+	book, err := books.Update(id, set)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Book not found",
 		})
 	}
+	publish(c, "update", book)
+
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Book updated successfully",
+			"book":    book,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusOK).JSON(bookResource(*book))
+}
+
+// patchBookFields handles PATCH /books/:id/fields - updates only the fields
+// present in the request body, leaving the rest of the book untouched
+func patchBookFields(c *fiber.Ctx) error {
+	id, err := parseBookID(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid book ID",
+		})
+	}
 
-	var updates Book
+	var updates models.Book
 	if err := c.BodyParser(&updates); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error":   "Cannot parse JSON",
@@ -164,68 +279,117 @@ func updateBook(c *fiber.Ctx) error {
 		})
 	}
 
-	// Keep the original ID and update the book
-	updates.ID = id
-	library[index] = updates
+	supplied, err := validate.SuppliedKeys(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Cannot parse JSON",
+			"details": err.Error(),
+		})
+	}
+
+	if errs := validate.PartialStruct(&updates, supplied); errs != nil {
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{"errors": errs})
+	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Book updated successfully",
-		"book":    updates,
-	})
+	set := bson.M{}
+	for key, value := range map[string]any{
+		"title":          updates.Title,
+		"author":         updates.Author,
+		"genre":          updates.Genre,
+		"pages":          updates.Pages,
+		"available":      updates.Available,
+		"published_year": updates.PublishedYear,
+	} {
+		if _, ok := supplied[key]; ok {
+			set[key] = value
+		}
+	}
+
+	book, err := books.Update(id, set)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Book not found",
+		})
+	}
+	publish(c, "update", book)
+
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Book updated successfully",
+			"book":    book,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusOK).JSON(bookResource(*book))
 }
 
 // toggleBookAvailability handles PATCH /books/:id - toggles the availability status
 func toggleBookAvailability(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := parseBookID(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid book ID",
 		})
 	}
 
-	book, index, err := findBookByID(id)
+	book, err := books.FindByID(id)
 	if err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Book not found",
 		})
 	}
 
-	// Toggle availability status
-	library[index].Available = !book.Available
+	updated, err := books.Update(id, bson.M{"available": !book.Available})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update book",
+		})
+	}
+	publish(c, "toggle", updated)
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Book availability updated",
-		"book":    library[index],
-	})
+	if wantsLegacyJSON(c) {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Book availability updated",
+			"book":    updated,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "application/hal+json")
+	return c.Status(fiber.StatusOK).JSON(bookResource(*updated))
 }
 
 // deleteBook handles DELETE /books/:id - removes a book from the library
 func deleteBook(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := parseBookID(c)
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Invalid book ID",
 		})
 	}
 
-	for i, book := range library {
-		if book.ID == id {
-			library = slices.Delete(library, i, i+1)
-			return c.Status(fiber.StatusOK).JSON(fiber.Map{
-				"message": "Book deleted successfully",
-			})
-		}
+	if err := books.Delete(id); err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Book not found",
+		})
 	}
+	publish(c, "delete", fiber.Map{"id": id.Hex()})
 
-	return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-		"error": "Book not found",
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Book deleted successfully",
 	})
 }
 
 // main function sets up the Fiber app and defines routes
 func main() {
+	cfg := config.Load()
+	db, err := config.Connect(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	books = repositories.NewBookRepository(db)
+	collectionsHandler := NewCollectionHandler(repositories.NewCollectionRepository(db), books)
+
 	// Create Fiber app with custom error handler
 	app := fiber.New(fiber.Config{
 		ErrorHandler: func(c *fiber.Ctx, err error) error {
@@ -254,7 +418,13 @@ func main() {
 				"GET /books/:id - Get book by ID",
 				"PUT /books/:id - Update book",
 				"PATCH /books/:id - Toggle availability",
+				"PATCH /books/:id/fields - Partially update book fields",
 				"DELETE /books/:id - Delete book",
+				"GET /collections - List collections",
+				"POST /collections - Create collection",
+				"GET /collections/:id/books - Get books in a collection",
+				"GET /events - SSE stream of book mutations",
+				"GET /ws - WebSocket stream of book mutations",
 			},
 		})
 	})
@@ -265,8 +435,24 @@ func main() {
 	app.Get("/books/:id", getBook)
 	app.Put("/books/:id", updateBook)
 	app.Patch("/books/:id", toggleBookAvailability)
+	app.Patch("/books/:id/fields", patchBookFields)
 	app.Delete("/books/:id", deleteBook)
 
+	// Collection routes
+	app.Post("/collections", collectionsHandler.create)
+	app.Get("/collections", collectionsHandler.list)
+	app.Get("/collections/:id", collectionsHandler.get)
+	app.Put("/collections/:id", collectionsHandler.update)
+	app.Delete("/collections/:id", collectionsHandler.delete)
+	app.Get("/collections/:id/books", collectionsHandler.booksInCollection)
+	app.Post("/collections/:id/books/:bookId", collectionsHandler.addBook)
+	app.Delete("/collections/:id/books/:bookId", collectionsHandler.removeBook)
+
+	// Change stream routes
+	app.Get("/events", streamEvents)
+	app.Use("/ws", upgradeWebSocket)
+	app.Get("/ws", streamWebSocket)
+
 	// Start server
 	log.Fatal(app.Listen(":8080"))
 }
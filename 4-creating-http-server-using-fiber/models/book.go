@@ -0,0 +1,14 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Book represents a book in our library
+type Book struct {
+	ID            primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Title         string             `json:"title" bson:"title" validate:"required,min=1,max=200"`
+	Author        string             `json:"author" bson:"author" validate:"required"`
+	Genre         string             `json:"genre" bson:"genre" validate:"omitempty,oneof=Technology Fiction NonFiction Biography Fantasy Science Mystery"`
+	Pages         int                `json:"pages" bson:"pages" validate:"gt=0"`
+	Available     bool               `json:"available" bson:"available"`
+	PublishedYear int                `json:"published_year" bson:"published_year" validate:"gte=0,maxyear"`
+}
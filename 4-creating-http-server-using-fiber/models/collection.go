@@ -0,0 +1,11 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// Collection groups a set of books under a named, described shelf
+type Collection struct {
+	ID          primitive.ObjectID   `json:"id" bson:"_id,omitempty"`
+	Name        string               `json:"name" bson:"name"`
+	Description string               `json:"description" bson:"description"`
+	BookIDs     []primitive.ObjectID `json:"book_ids" bson:"book_ids"`
+}
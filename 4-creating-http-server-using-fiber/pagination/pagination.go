@@ -0,0 +1,100 @@
+// Package pagination pushes cursor-based, sortable pagination down into
+// Mongo (Skip/Limit/Sort) for the GET /books and GET /todos list handlers,
+// rather than loading every matching document into memory to slice a page
+// out of it.
+package pagination
+
+import (
+	"encoding/base64"
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// DefaultLimit is used when the caller omits ?limit=
+	DefaultLimit = 20
+	// MaxLimit is the largest page size a caller may request
+	MaxLimit = 100
+)
+
+// Opts configures a single page of a Mongo query
+type Opts struct {
+	// Limit is the page size; non-positive values fall back to DefaultLimit
+	// and values above MaxLimit are capped at MaxLimit
+	Limit int
+	// Cursor is the base64-encoded skip offset of this page, as returned by
+	// a previous call's next/prev cursor
+	Cursor string
+	// Sort is the Mongo sort spec results are ordered by before paging. A
+	// nil Sort leaves ordering up to Mongo's natural order.
+	Sort bson.D
+}
+
+// FindOptions builds the Mongo find options that page opts.Sort by
+// opts.Limit starting at opts.Cursor, so the database - not the app - does
+// the sorting and slicing
+func FindOptions(opts Opts) *options.FindOptions {
+	fo := options.Find().SetLimit(int64(clampLimit(opts.Limit))).SetSkip(decodeCursor(opts.Cursor))
+	if len(opts.Sort) > 0 {
+		fo.SetSort(opts.Sort)
+	}
+	return fo
+}
+
+// Cursors computes the cursors for the next and previous pages from the
+// total number of documents matching the filter and how many were returned
+// for the current page
+func Cursors(opts Opts, total int64, returned int) (nextCursor string, prevCursor string) {
+	limit := int64(clampLimit(opts.Limit))
+	skip := decodeCursor(opts.Cursor)
+
+	if skip+int64(returned) < total {
+		nextCursor = encodeCursor(skip + limit)
+	}
+
+	if skip > 0 {
+		if prevSkip := skip - limit; prevSkip > 0 {
+			prevCursor = encodeCursor(prevSkip)
+		} else {
+			prevCursor = encodeCursor(0)
+		}
+	}
+
+	return nextCursor, prevCursor
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+func encodeCursor(skip int64) string {
+	return base64.URLEncoding.EncodeToString([]byte(strconv.FormatInt(skip, 10)))
+}
+
+// decodeCursor recovers the skip offset encoded in cursor, defaulting to 0
+// (the first page) for an empty or malformed cursor
+func decodeCursor(cursor string) int64 {
+	if cursor == "" {
+		return 0
+	}
+
+	raw, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0
+	}
+
+	skip, err := strconv.ParseInt(string(raw), 10, 64)
+	if err != nil || skip < 0 {
+		return 0
+	}
+
+	return skip
+}
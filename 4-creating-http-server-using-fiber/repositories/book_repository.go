@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BookRepository persists Book documents in the "books" collection
+type BookRepository struct {
+	collection *mongo.Collection
+}
+
+// NewBookRepository wraps the given database's "books" collection
+func NewBookRepository(db *mongo.Database) *BookRepository {
+	return &BookRepository{collection: db.Collection("books")}
+}
+
+func (r *BookRepository) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+// Create inserts a new book and populates its generated ID
+func (r *BookRepository) Create(book *models.Book) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	book.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, book)
+	return err
+}
+
+// FindByID looks up a single book by its ObjectID
+func (r *BookRepository) FindByID(id primitive.ObjectID) (*models.Book, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	var book models.Book
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&book); err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// Find returns the books matching the given Mongo filter, optionally
+// narrowed by Mongo find options such as Skip/Limit/Sort
+func (r *BookRepository) Find(filter bson.M, opts ...*options.FindOptions) ([]models.Book, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	books := []models.Book{}
+	if err := cursor.All(ctx, &books); err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+// Count returns the number of books matching the given Mongo filter
+func (r *BookRepository) Count(filter bson.M) (int64, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	return r.collection.CountDocuments(ctx, filter)
+}
+
+// Update applies the given field updates to a book and returns the updated document
+func (r *BookRepository) Update(id primitive.ObjectID, update bson.M) (*models.Book, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	after := options.After
+	var book models.Book
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": update},
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after},
+	).Decode(&book)
+	if err != nil {
+		return nil, err
+	}
+
+	return &book, nil
+}
+
+// Delete removes a book by its ObjectID
+func (r *BookRepository) Delete(id primitive.ObjectID) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// CollectionRepository persists Collection documents in the "collections" collection
+type CollectionRepository struct {
+	collection *mongo.Collection
+}
+
+// NewCollectionRepository wraps the given database's "collections" collection
+func NewCollectionRepository(db *mongo.Database) *CollectionRepository {
+	return &CollectionRepository{collection: db.Collection("collections")}
+}
+
+func (r *CollectionRepository) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), 5*time.Second)
+}
+
+// Create inserts a new collection and populates its generated ID
+func (r *CollectionRepository) Create(col *models.Collection) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	col.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(ctx, col)
+	return err
+}
+
+// FindByID looks up a single collection by its ObjectID
+func (r *CollectionRepository) FindByID(id primitive.ObjectID) (*models.Collection, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	var col models.Collection
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&col); err != nil {
+		return nil, err
+	}
+
+	return &col, nil
+}
+
+// Find returns collections matching filter, paginated by page (1-indexed) and limit
+func (r *CollectionRepository) Find(filter bson.M, page, limit int64) ([]models.Collection, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	opts := options.Find().SetSkip((page - 1) * limit).SetLimit(limit)
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	cols := []models.Collection{}
+	if err := cursor.All(ctx, &cols); err != nil {
+		return nil, err
+	}
+
+	return cols, nil
+}
+
+// Update applies the given field updates to a collection and returns the updated document
+func (r *CollectionRepository) Update(id primitive.ObjectID, update bson.M) (*models.Collection, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	after := options.After
+	var col models.Collection
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": update},
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after},
+	).Decode(&col)
+	if err != nil {
+		return nil, err
+	}
+
+	return &col, nil
+}
+
+// Delete removes a collection by its ObjectID
+func (r *CollectionRepository) Delete(id primitive.ObjectID) error {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return nil
+}
+
+// AddBook appends a book ID to a collection's book list, if not already present
+func (r *CollectionRepository) AddBook(id, bookID primitive.ObjectID) (*models.Collection, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	after := options.After
+	var col models.Collection
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$addToSet": bson.M{"book_ids": bookID}},
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after},
+	).Decode(&col)
+	if err != nil {
+		return nil, err
+	}
+
+	return &col, nil
+}
+
+// RemoveBook removes a book ID from a collection's book list
+func (r *CollectionRepository) RemoveBook(id, bookID primitive.ObjectID) (*models.Collection, error) {
+	ctx, cancel := r.context()
+	defer cancel()
+
+	after := options.After
+	var col models.Collection
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$pull": bson.M{"book_ids": bookID}},
+		&options.FindOneAndUpdateOptions{ReturnDocument: &after},
+	).Decode(&col)
+	if err != nil {
+		return nil, err
+	}
+
+	return &col, nil
+}
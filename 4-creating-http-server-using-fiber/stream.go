@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TechAtikiN/the-go-brewery/4-creating-http-server-using-fiber/events"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/websocket/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// hub fans out book mutation events to every /events and /ws subscriber
+var hub = events.NewHub()
+
+// publish broadcasts a mutation to hub, tagging it with the requester's
+// X-Request-Source header so that client's own subscription can skip the echo
+func publish(c *fiber.Ctx, action string, data any) {
+	hub.Publish(events.Event{
+		Object: "book",
+		Action: action,
+		Data:   data,
+		Source: c.Get("X-Request-Source"),
+	})
+}
+
+// streamEvents handles GET /events - an SSE stream of book mutations
+func streamEvents(c *fiber.Ctx) error {
+	sub := hub.Subscribe(c.Get("X-Request-Source"))
+
+	c.Set("Content-Type", "text/event-stream")
+	c.Set("Cache-Control", "no-cache")
+	c.Set("Connection", "keep-alive")
+
+	c.Context().SetBodyStreamWriter(fasthttp.StreamWriter(func(w *bufio.Writer) {
+		defer hub.Unsubscribe(sub)
+
+		for event := range sub.Events {
+			payload, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "data: %s\n\n", payload); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	}))
+
+	return nil
+}
+
+// upgradeWebSocket rejects non-upgrade requests to /ws and stashes the
+// requester's X-Request-Source for the handler below
+func upgradeWebSocket(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	c.Locals("source", c.Get("X-Request-Source"))
+	return c.Next()
+}
+
+// streamWebSocket handles GET /ws - a WebSocket stream of book mutations
+var streamWebSocket = websocket.New(func(c *websocket.Conn) {
+	source, _ := c.Locals("source").(string)
+	sub := hub.Subscribe(source)
+	defer hub.Unsubscribe(sub)
+
+	for event := range sub.Events {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			continue
+		}
+		if err := c.WriteMessage(websocket.TextMessage, payload); err != nil {
+			break
+		}
+	}
+})
@@ -0,0 +1,62 @@
+// Package hal provides a minimal HAL+JSON (Hypertext Application Language)
+// envelope for wrapping API payloads with "_links" and "_embedded" sections.
+package hal
+
+import "encoding/json"
+
+// Link is a single HAL link relation
+type Link struct {
+	Href string `json:"href"`
+}
+
+// Resource wraps a payload so that it marshals with a "_links" section and,
+// once items have been embedded, an "_embedded" section alongside its own fields
+type Resource struct {
+	Payload  any
+	Links    map[string]Link
+	Embedded map[string][]Resource
+}
+
+// NewResource creates a HAL resource for payload with a "self" link pointing at self
+func NewResource(self string, payload any) Resource {
+	return Resource{
+		Payload: payload,
+		Links:   map[string]Link{"self": {Href: self}},
+	}
+}
+
+// AddLink attaches an additional link relation and returns the resource for chaining
+func (r Resource) AddLink(rel, href string) Resource {
+	r.Links[rel] = Link{Href: href}
+	return r
+}
+
+// Embed attaches one or more child resources under the given relation and
+// returns the resource for chaining
+func (r Resource) Embed(rel string, items ...Resource) Resource {
+	if r.Embedded == nil {
+		r.Embedded = map[string][]Resource{}
+	}
+	r.Embedded[rel] = items
+	return r
+}
+
+// MarshalJSON flattens the payload's own fields alongside "_links" and "_embedded"
+func (r Resource) MarshalJSON() ([]byte, error) {
+	raw, err := json.Marshal(r.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	merged := map[string]any{}
+	if err := json.Unmarshal(raw, &merged); err != nil {
+		return nil, err
+	}
+
+	merged["_links"] = r.Links
+	if len(r.Embedded) > 0 {
+		merged["_embedded"] = r.Embedded
+	}
+
+	return json.Marshal(merged)
+}
@@ -0,0 +1,95 @@
+// Package validate wraps go-playground/validator to turn struct validation
+// failures into API-friendly field errors, with support for validating only
+// the fields a PATCH-style request actually supplied.
+package validate
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError describes a single failed validation rule
+type FieldError struct {
+	Field   string `json:"field"`
+	Tag     string `json:"tag"`
+	Message string `json:"message"`
+}
+
+var instance = validator.New()
+
+func init() {
+	instance.RegisterValidation("maxyear", func(fl validator.FieldLevel) bool {
+		return fl.Field().Int() <= int64(time.Now().Year())
+	})
+}
+
+// Struct validates every `validate`-tagged field on v
+func Struct(v any) []FieldError {
+	return format(instance.Struct(v))
+}
+
+// SuppliedKeys decodes a JSON request body into its top-level keys, so callers
+// can tell which fields a PATCH-style request actually intended to change
+func SuppliedKeys(raw []byte) (map[string]json.RawMessage, error) {
+	supplied := map[string]json.RawMessage{}
+	if len(raw) == 0 {
+		return supplied, nil
+	}
+	err := json.Unmarshal(raw, &supplied)
+	return supplied, err
+}
+
+// PartialStruct validates only the struct fields whose JSON key is present in
+// supplied, so omitted fields don't trip "required" on a partial update
+func PartialStruct(v any, supplied map[string]json.RawMessage) []FieldError {
+	fields := structFieldNames(v, supplied)
+	if len(fields) == 0 {
+		return nil
+	}
+	return format(instance.StructPartial(v, fields...))
+}
+
+func structFieldNames(v any, supplied map[string]json.RawMessage) []string {
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		jsonTag := strings.Split(field.Tag.Get("json"), ",")[0]
+		if _, ok := supplied[jsonTag]; ok {
+			fields = append(fields, field.Name)
+		}
+	}
+
+	return fields
+}
+
+func format(err error) []FieldError {
+	if err == nil {
+		return nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	out := make([]FieldError, len(validationErrors))
+	for i, fe := range validationErrors {
+		out[i] = FieldError{
+			Field:   fe.Field(),
+			Tag:     fe.Tag(),
+			Message: fmt.Sprintf("%s failed on the '%s' validation", fe.Field(), fe.Tag()),
+		}
+	}
+
+	return out
+}